@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//go:embed themes/*.rasi
+var embeddedThemesFS embed.FS
+
+//go:embed scripts/*.sh
+var embeddedScriptsFS embed.FS
+
+// restoreMode controls how extractEmbedded treats files that already exist
+// on disk.
+type restoreMode int
+
+const (
+	restoreMissingOnly restoreMode = iota // never touch an existing file
+	restoreReset                          // overwrite on confirmed conflict
+)
+
+func dirIsEmpty(dir string) bool {
+	entries, err := ioutil.ReadDir(dir)
+	return err != nil || len(entries) == 0
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// extractEmbedded materializes every file under subdir in fsys into
+// destDir. With restoreMissingOnly it leaves existing files alone; with
+// restoreReset it compares sha256 against the embedded copy and asks the
+// user to confirm before clobbering a locally-edited file.
+func (rm *RofiManager) extractEmbedded(fsys embed.FS, subdir, destDir string, perm os.FileMode, mode restoreMode) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, subdir)
+	if err != nil {
+		return nil, err
+	}
+	written := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.Join(subdir, entry.Name()))
+		if err != nil {
+			return written, err
+		}
+		dest := filepath.Join(destDir, entry.Name())
+		if _, err := os.Stat(dest); err == nil {
+			if mode == restoreMissingOnly {
+				continue
+			}
+			localHash, err := hashFile(dest)
+			if err == nil && localHash == hashBytes(data) {
+				continue
+			}
+			overwrite := rm.rofiMenu(fmt.Sprintf("%s differs from the bundled default. Overwrite?", entry.Name()), []string{"No", "Yes"}, rm.getEnabledTheme())
+			if overwrite != "Yes" {
+				continue
+			}
+		}
+		if err := ioutil.WriteFile(dest, data, perm); err != nil {
+			return written, err
+		}
+		written = append(written, entry.Name())
+	}
+	return written, nil
+}
+
+// resetToDefaultsMenu is the "Reset to Defaults" menu action: re-extract
+// every bundled theme and script, prompting before overwriting any file
+// that has diverged from the bundled copy.
+func (rm *RofiManager) resetToDefaultsMenu() {
+	rm.extractEmbedded(embeddedThemesFS, "themes", rm.themesDir, 0644, restoreReset)
+	rm.extractEmbedded(embeddedScriptsFS, "scripts", rm.scriptsDir, 0755, restoreReset)
+	rm.showInfo("Reset bundled themes and scripts to their defaults.")
+}
+
+// restoreMissingDefaultsMenu is the "Restore Missing Defaults" menu action:
+// re-extract only the bundled files that are no longer present, without
+// touching anything the user has edited.
+func (rm *RofiManager) restoreMissingDefaultsMenu() {
+	themes, _ := rm.extractEmbedded(embeddedThemesFS, "themes", rm.themesDir, 0644, restoreMissingOnly)
+	scripts, _ := rm.extractEmbedded(embeddedScriptsFS, "scripts", rm.scriptsDir, 0755, restoreMissingOnly)
+	rm.showInfo(fmt.Sprintf("Restored %d theme(s) and %d script(s).", len(themes), len(scripts)))
+}
+
+// firstRunWizard walks a fresh install through picking a starter theme,
+// toggling default modes, and enabling a starter script, reusing the
+// existing menu actions so the flow looks the same as everyday use.
+func (rm *RofiManager) firstRunWizard() {
+	rm.showInfo("Welcome to Rofi Manager! Let's get you set up.")
+
+	themes := rm.loadThemes()
+	if len(themes) > 0 {
+		options := append([]string{"Skip"}, themes...)
+		choice := rm.rofiMenu("Pick a starter theme", options, "")
+		if choice != "" && choice != "Skip" {
+			rm.setEnabledTheme(choice)
+		}
+	}
+
+	rm.toggleModes()
+
+	if len(rm.loadScripts()) > 0 {
+		enable := rm.rofiMenu("Enable a starter script now?", []string{"No", "Yes"}, rm.getEnabledTheme())
+		if enable == "Yes" {
+			rm.enableScript()
+		}
+	}
+}
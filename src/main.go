@@ -7,18 +7,26 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/ini.v1"
 )
 
+const defaultProfileName = "default"
+
 type RofiManager struct {
-	baseDir    string
-	themesDir  string
-	scriptsDir string
-	configPath string
-	config     *ini.File
-	allModes   []string
+	baseDir       string
+	themesDir     string
+	scriptsDir    string
+	configDir     string
+	statePath     string
+	activeProfile string
+	config        *ini.File
+	profileConfig *ini.File
+	allModes      []string
+	themes        *ThemeCollection
+	firstRun      bool
 }
 
 func NewRofiManager() *RofiManager {
@@ -26,45 +34,193 @@ func NewRofiManager() *RofiManager {
 	baseDir := filepath.Join(home, ".config", "rofi-manager")
 	themesDir := filepath.Join(baseDir, "themes")
 	scriptsDir := filepath.Join(baseDir, "scripts")
-	configPath := filepath.Join(baseDir, "config.conf")
+	configDir := filepath.Join(baseDir, "config")
+	statePath := filepath.Join(baseDir, "state.conf")
 	manager := &RofiManager{
 		baseDir:    baseDir,
 		themesDir:  themesDir,
 		scriptsDir: scriptsDir,
-		configPath: configPath,
+		configDir:  configDir,
+		statePath:  statePath,
 		allModes:   []string{"run", "drun", "window", "ssh", "filebrowser", "key"},
 	}
+	manager.activeProfile = manager.determineProfile()
 	manager.ensureConfigDirs()
 	manager.loadConfig()
+	manager.themes = NewThemeCollection(manager)
+	if manager.firstRun {
+		manager.firstRunWizard()
+	}
 	return manager
 }
 
+// determineProfile picks the active profile from $ROFI_MANAGER_PROFILE, then
+// a --profile flag, then the last-used profile recorded in state.conf,
+// falling back to defaultProfileName.
+func (rm *RofiManager) determineProfile() string {
+	if p := os.Getenv("ROFI_MANAGER_PROFILE"); p != "" {
+		return p
+	}
+	for i, arg := range os.Args {
+		if arg == "--profile" && i+1 < len(os.Args) {
+			return os.Args[i+1]
+		}
+		if strings.HasPrefix(arg, "--profile=") {
+			return strings.TrimPrefix(arg, "--profile=")
+		}
+	}
+	if state, err := ini.Load(rm.statePath); err == nil {
+		if p := state.Section("state").Key("profile").String(); p != "" {
+			return p
+		}
+	}
+	return defaultProfileName
+}
+
+func (rm *RofiManager) defaultConfigDir() string {
+	return filepath.Join(rm.configDir, "_default")
+}
+
+func (rm *RofiManager) profileConfigDir(profile string) string {
+	return filepath.Join(rm.configDir, sanitizeProfileName(profile))
+}
+
+// sanitizeProfileName strips any path components from a profile name so
+// values sourced from $ROFI_MANAGER_PROFILE, --profile, or state.conf can
+// never resolve outside configDir.
+func sanitizeProfileName(name string) string {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return defaultProfileName
+	}
+	return name
+}
+
+func (rm *RofiManager) profileMainConfPath(profile string) string {
+	return filepath.Join(rm.profileConfigDir(profile), "main.conf")
+}
+
 func (rm *RofiManager) ensureConfigDirs() {
 	os.MkdirAll(rm.themesDir, 0755)
 	os.MkdirAll(rm.scriptsDir, 0755)
-	if _, err := os.Stat(rm.configPath); os.IsNotExist(err) {
+	os.MkdirAll(rm.defaultConfigDir(), 0755)
+	os.MkdirAll(rm.profileConfigDir(rm.activeProfile), 0755)
+
+	if dirIsEmpty(rm.themesDir) || dirIsEmpty(rm.scriptsDir) {
+		rm.firstRun = true
+		rm.extractEmbedded(embeddedThemesFS, "themes", rm.themesDir, 0644, restoreMissingOnly)
+		rm.extractEmbedded(embeddedScriptsFS, "scripts", rm.scriptsDir, 0755, restoreMissingOnly)
+	}
+
+	defaultMain := filepath.Join(rm.defaultConfigDir(), "main.conf")
+	if _, err := os.Stat(defaultMain); os.IsNotExist(err) {
 		cfg := ini.Empty()
 		cfg.Section("modes").Key("enabled").SetValue("run,drun,window")
 		cfg.Section("scripts").Key("enabled").SetValue("")
 		cfg.Section("theme").Key("enabled").SetValue("")
-		cfg.SaveTo(rm.configPath)
+		cfg.SaveTo(defaultMain)
+	}
+	if _, err := os.Stat(rm.statePath); os.IsNotExist(err) {
+		rm.persistActiveProfile()
 	}
 }
 
+// loadConfig builds the effective configuration by merging every *.conf
+// file under config/_default with every *.conf file under config/<profile>
+// on top, so profile files override the defaults on a per-key basis. The
+// profile-only layer is kept separately so saveConfig can persist just the
+// profile's overrides instead of re-baking the defaults into it.
 func (rm *RofiManager) loadConfig() {
-	cfg, err := ini.Load(rm.configPath)
+	cfg := ini.Empty()
+	mergeConfDirInto(cfg, rm.defaultConfigDir())
+
+	profileCfg := ini.Empty()
+	mergeConfDirInto(profileCfg, rm.profileConfigDir(rm.activeProfile))
+	mergeIniInto(cfg, profileCfg)
+
+	rm.config = cfg
+	rm.profileConfig = profileCfg
+}
+
+// mergeConfDirInto loads every *.conf file in dir (in sorted order) and
+// merges it into cfg, later files overriding earlier ones.
+func mergeConfDirInto(cfg *ini.File, dir string) {
+	files, err := ioutil.ReadDir(dir)
 	if err != nil {
-		cfg = ini.Empty()
-		cfg.Section("modes").Key("enabled").SetValue("run,drun,window")
-		cfg.Section("scripts").Key("enabled").SetValue("")
-		cfg.Section("theme").Key("enabled").SetValue("")
-		cfg.SaveTo(rm.configPath)
+		return
 	}
-	rm.config = cfg
+	names := []string{}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".conf") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src, err := ini.Load(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		mergeIniInto(cfg, src)
+	}
+}
+
+// mergeIniInto copies every section/key from src into dst, src's values
+// winning on conflict.
+func mergeIniInto(dst, src *ini.File) {
+	for _, section := range src.Sections() {
+		dstSection := dst.Section(section.Name())
+		for _, key := range section.Keys() {
+			dstSection.Key(key.Name()).SetValue(key.Value())
+		}
+	}
+}
+
+// setConfigValue updates a key on both the effective (merged) config and the
+// profile-only layer, then persists just the profile layer.
+func (rm *RofiManager) setConfigValue(section, key, value string) {
+	rm.config.Section(section).Key(key).SetValue(value)
+	rm.profileConfig.Section(section).Key(key).SetValue(value)
+	rm.saveConfig()
 }
 
+// saveConfig persists only the active profile's overrides to its main.conf,
+// leaving the shared _default layer untouched so later _default edits keep
+// reaching this profile for any key it hasn't overridden.
 func (rm *RofiManager) saveConfig() {
-	rm.config.SaveTo(rm.configPath)
+	rm.profileConfig.SaveTo(rm.profileMainConfPath(rm.activeProfile))
+}
+
+func (rm *RofiManager) persistActiveProfile() {
+	state := ini.Empty()
+	state.Section("state").Key("profile").SetValue(rm.activeProfile)
+	state.SaveTo(rm.statePath)
+}
+
+// listProfiles scans config/ for profile directories, excluding the shared
+// _default base layer.
+func (rm *RofiManager) listProfiles() []string {
+	entries, err := ioutil.ReadDir(rm.configDir)
+	if err != nil {
+		return nil
+	}
+	profiles := []string{}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "_default" {
+			profiles = append(profiles, e.Name())
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// switchProfile changes the active profile, reloads the merged config, and
+// records the new profile as last-used in state.conf.
+func (rm *RofiManager) switchProfile(profile string) {
+	os.MkdirAll(rm.profileConfigDir(profile), 0755)
+	rm.activeProfile = profile
+	rm.loadConfig()
+	rm.persistActiveProfile()
 }
 
 func (rm *RofiManager) getEnabledModes() []string {
@@ -80,8 +236,7 @@ func (rm *RofiManager) getEnabledModes() []string {
 }
 
 func (rm *RofiManager) setEnabledModes(modes []string) {
-	rm.config.Section("modes").Key("enabled").SetValue(strings.Join(modes, ","))
-	rm.saveConfig()
+	rm.setConfigValue("modes", "enabled", strings.Join(modes, ","))
 }
 
 func (rm *RofiManager) getEnabledScripts() []string {
@@ -97,8 +252,7 @@ func (rm *RofiManager) getEnabledScripts() []string {
 }
 
 func (rm *RofiManager) setEnabledScripts(scripts []string) {
-	rm.config.Section("scripts").Key("enabled").SetValue(strings.Join(scripts, ","))
-	rm.saveConfig()
+	rm.setConfigValue("scripts", "enabled", strings.Join(scripts, ","))
 }
 
 func (rm *RofiManager) getEnabledTheme() string {
@@ -106,8 +260,7 @@ func (rm *RofiManager) getEnabledTheme() string {
 }
 
 func (rm *RofiManager) setEnabledTheme(theme string) {
-	rm.config.Section("theme").Key("enabled").SetValue(theme)
-	rm.saveConfig()
+	rm.setConfigValue("theme", "enabled", theme)
 }
 
 func (rm *RofiManager) loadScripts() []string {
@@ -133,9 +286,25 @@ func (rm *RofiManager) loadThemes() []string {
 }
 
 func (rm *RofiManager) rofiMenu(prompt string, options []string, theme string) string {
-	rofiCmd := []string{"-dmenu", "-p", prompt}
+	themePath := ""
 	if theme != "" {
-		themePath := filepath.Join(rm.themesDir, theme)
+		composed, err := rm.composeTheme(theme)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rofi-manager: theme %q failed to compose, disabling it: %s\n", theme, err)
+			rm.setEnabledTheme("")
+		} else {
+			themePath = composed
+		}
+	}
+	return rm.rofiMenuWithThemePath(prompt, options, themePath)
+}
+
+// rofiMenuWithThemePath is like rofiMenu but takes a direct path to a theme
+// file instead of a name resolved under themesDir, so callers such as the
+// theme gallery preview can point rofi at a temp file.
+func (rm *RofiManager) rofiMenuWithThemePath(prompt string, options []string, themePath string) string {
+	rofiCmd := []string{"-dmenu", "-p", prompt}
+	if themePath != "" {
 		if _, err := os.Stat(themePath); err == nil {
 			rofiCmd = append(rofiCmd, "-theme", themePath)
 		}
@@ -230,18 +399,30 @@ func (rm *RofiManager) enableScript() {
 	}
 	for {
 		options := []string{}
+		labelToScript := map[string]string{}
 		for _, script := range scripts {
+			meta := loadScriptMeta(rm.scriptsDir, script)
 			mark := "[ ]"
 			if enabledSet[script] {
 				mark = "[x]"
 			}
-			options = append(options, fmt.Sprintf("%s %s", mark, script))
+			label := fmt.Sprintf("%s %s", mark, meta.DisplayName)
+			options = append(options, label)
+			labelToScript[label] = script
 		}
+		options = append(options, "Edit Script Metadata")
 		choice := rm.rofiMenu("Toggle scripts (Enter to finish)", options, rm.getEnabledTheme())
 		if choice == "" {
 			break
 		}
-		script := strings.TrimSpace(choice[4:])
+		if choice == "Edit Script Metadata" {
+			rm.editScriptMetadataMenu(scripts)
+			continue
+		}
+		script, ok := labelToScript[choice]
+		if !ok {
+			continue
+		}
 		if enabledSet[script] {
 			delete(enabledSet, script)
 		} else {
@@ -321,18 +502,29 @@ func (rm *RofiManager) toggleModes() {
 
 func (rm *RofiManager) selectMode() {
 	enabledModes := rm.getEnabledModes()
-	if len(enabledModes) == 0 {
+	scriptEntries := rm.enabledScriptEntries()
+	options := append([]string{}, enabledModes...)
+	if len(scriptEntries) > 0 {
+		options = append(options, "Scripts")
+	}
+	if len(options) == 0 {
 		rm.showInfo("No modes enabled.")
 		return
 	}
-	choice := rm.rofiMenu("Select mode", enabledModes, rm.getEnabledTheme())
+	choice := rm.rofiMenu("Select mode", options, rm.getEnabledTheme())
+	if choice == "Scripts" {
+		rm.selectScriptEntry(scriptEntries)
+		return
+	}
 	for _, mode := range enabledModes {
 		if choice == mode {
 			theme := rm.getEnabledTheme()
 			rofiCmd := []string{"-show", mode}
 			if theme != "" {
-				themePath := filepath.Join(rm.themesDir, theme)
-				if _, err := os.Stat(themePath); err == nil {
+				themePath, err := rm.composeTheme(theme)
+				if err != nil {
+					rm.showInfo(fmt.Sprintf("Theme composition failed:\n%s", err))
+				} else if themePath != "" {
 					rofiCmd = append(rofiCmd, "-theme", themePath)
 				}
 			}
@@ -352,6 +544,15 @@ func (rm *RofiManager) run() {
 			"Enable Theme",
 			"Add Script",
 			"Add Theme",
+			"Browse Online Themes",
+			"Preview Theme",
+			"Update Theme Index",
+			"Switch Profile",
+			"New Profile",
+			"Clone Profile",
+			"Delete Profile",
+			"Reset to Defaults",
+			"Restore Missing Defaults",
 			"Exit",
 		}
 		choice := rm.rofiMenu("Rofi Manager", mainOptions, rm.getEnabledTheme())
@@ -368,6 +569,24 @@ func (rm *RofiManager) run() {
 			rm.addScript()
 		case "Add Theme":
 			rm.addTheme()
+		case "Browse Online Themes":
+			rm.themes.browseOnlineThemes()
+		case "Preview Theme":
+			rm.themes.previewThemeMenu()
+		case "Update Theme Index":
+			rm.themes.updateThemeIndex()
+		case "Switch Profile":
+			rm.switchProfileMenu()
+		case "New Profile":
+			rm.newProfileMenu()
+		case "Clone Profile":
+			rm.cloneProfileMenu()
+		case "Delete Profile":
+			rm.deleteProfileMenu()
+		case "Reset to Defaults":
+			rm.resetToDefaultsMenu()
+		case "Restore Missing Defaults":
+			rm.restoreMissingDefaultsMenu()
 		case "Exit", "":
 			return
 		}
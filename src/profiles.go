@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isSafeProfileName rejects names that would let profileConfigDir escape
+// configDir (path separators, "." and "..").
+func isSafeProfileName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, "/\\")
+}
+
+// switchProfileMenu is the "Switch Profile" menu action: pick any existing
+// profile (or the default) and make it active.
+func (rm *RofiManager) switchProfileMenu() {
+	profiles := rm.listProfiles()
+	if len(profiles) == 0 {
+		profiles = []string{defaultProfileName}
+	}
+	options := []string{}
+	for _, p := range profiles {
+		mark := "[ ]"
+		if p == rm.activeProfile {
+			mark = "[x]"
+		}
+		options = append(options, fmt.Sprintf("%s %s", mark, p))
+	}
+	choice := rm.rofiMenu("Switch Profile", options, rm.getEnabledTheme())
+	if choice == "" {
+		return
+	}
+	profile := choice[4:]
+	rm.switchProfile(profile)
+	rm.showInfo(fmt.Sprintf("Switched to profile: %s", profile))
+}
+
+// newProfileMenu is the "New Profile" menu action: create an empty profile
+// that inherits everything from config/_default until overridden.
+func (rm *RofiManager) newProfileMenu() {
+	name := rm.rofiMenu("New profile name", []string{""}, rm.getEnabledTheme())
+	if name == "" {
+		return
+	}
+	if !isSafeProfileName(name) {
+		rm.showInfo("Invalid profile name.")
+		return
+	}
+	dir := rm.profileConfigDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		rm.showInfo(fmt.Sprintf("Profile %q already exists.", name))
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		rm.showInfo(fmt.Sprintf("Failed to create profile:\n%s", err))
+		return
+	}
+	switchNow := rm.rofiMenu(fmt.Sprintf("Created profile %q. Switch to it now?", name), []string{"No", "Yes"}, rm.getEnabledTheme())
+	if switchNow == "Yes" {
+		rm.switchProfile(name)
+	}
+}
+
+// cloneProfileMenu is the "Clone Profile" menu action: copy the active
+// profile's *.conf files into a new profile directory.
+func (rm *RofiManager) cloneProfileMenu() {
+	name := rm.rofiMenu(fmt.Sprintf("Clone %q as (new name)", rm.activeProfile), []string{""}, rm.getEnabledTheme())
+	if name == "" {
+		return
+	}
+	if !isSafeProfileName(name) {
+		rm.showInfo("Invalid profile name.")
+		return
+	}
+	destDir := rm.profileConfigDir(name)
+	if _, err := os.Stat(destDir); err == nil {
+		rm.showInfo(fmt.Sprintf("Profile %q already exists.", name))
+		return
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		rm.showInfo(fmt.Sprintf("Failed to create profile:\n%s", err))
+		return
+	}
+	srcDir := rm.profileConfigDir(rm.activeProfile)
+	files, _ := ioutil.ReadDir(srcDir)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(srcDir, f.Name()))
+		if err != nil {
+			continue
+		}
+		ioutil.WriteFile(filepath.Join(destDir, f.Name()), data, 0644)
+	}
+	rm.showInfo(fmt.Sprintf("Cloned %q to %q.", rm.activeProfile, name))
+}
+
+// deleteProfileMenu is the "Delete Profile" menu action. The active profile
+// cannot be deleted out from under itself; switch away first.
+func (rm *RofiManager) deleteProfileMenu() {
+	profiles := rm.listProfiles()
+	options := []string{}
+	for _, p := range profiles {
+		if p != rm.activeProfile {
+			options = append(options, p)
+		}
+	}
+	if len(options) == 0 {
+		rm.showInfo("No other profiles to delete.")
+		return
+	}
+	choice := rm.rofiMenu("Delete Profile", options, rm.getEnabledTheme())
+	if choice == "" {
+		return
+	}
+	confirm := rm.rofiMenu(fmt.Sprintf("Delete profile %q? This cannot be undone.", choice), []string{"No", "Yes"}, rm.getEnabledTheme())
+	if confirm != "Yes" {
+		return
+	}
+	if err := os.RemoveAll(rm.profileConfigDir(choice)); err != nil {
+		rm.showInfo(fmt.Sprintf("Failed to delete profile:\n%s", err))
+		return
+	}
+	rm.showInfo(fmt.Sprintf("Deleted profile: %s", choice))
+}
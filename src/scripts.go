@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// ScriptMeta is a script's sibling <name>.sh.ini metadata: a human-friendly
+// label plus how rofi-manager should present and dispatch it.
+type ScriptMeta struct {
+	DisplayName string
+	Description string
+	Icon        string
+	Hotkey      string
+	Args        []string
+	Mode        string // "run" (plain exec) or "custom-menu" (rofi script mode)
+	Env         []string
+}
+
+type scriptEntry struct {
+	Name string
+	Meta ScriptMeta
+}
+
+func scriptMetaPath(scriptsDir, script string) string {
+	return filepath.Join(scriptsDir, script+".ini")
+}
+
+func splitCSV(val string) []string {
+	parts := []string{}
+	for _, p := range strings.Split(val, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// loadScriptMeta reads a script's sidecar ini, if any, falling back to the
+// raw filename as its display name and "run" as its dispatch mode.
+func loadScriptMeta(scriptsDir, script string) ScriptMeta {
+	meta := ScriptMeta{DisplayName: script, Mode: "run"}
+	cfg, err := ini.Load(scriptMetaPath(scriptsDir, script))
+	if err != nil {
+		return meta
+	}
+	section := cfg.Section("script")
+	if v := section.Key("display_name").String(); v != "" {
+		meta.DisplayName = v
+	}
+	meta.Description = section.Key("description").String()
+	meta.Icon = section.Key("icon").String()
+	meta.Hotkey = section.Key("hotkey").String()
+	if v := section.Key("mode").String(); v != "" {
+		meta.Mode = v
+	}
+	meta.Args = splitCSV(section.Key("args").String())
+	meta.Env = splitCSV(section.Key("env").String())
+	return meta
+}
+
+// enabledScriptEntries resolves the enabled script names into their
+// metadata, skipping any that have since been removed from scriptsDir.
+func (rm *RofiManager) enabledScriptEntries() []scriptEntry {
+	entries := []scriptEntry{}
+	for _, name := range rm.getEnabledScripts() {
+		if _, err := os.Stat(filepath.Join(rm.scriptsDir, name)); err != nil {
+			continue
+		}
+		entries = append(entries, scriptEntry{Name: name, Meta: loadScriptMeta(rm.scriptsDir, name)})
+	}
+	return entries
+}
+
+// selectScriptEntry is the "Scripts" pseudo-mode: a rofi menu of enabled
+// scripts by display name, dispatched according to their metadata.
+func (rm *RofiManager) selectScriptEntry(entries []scriptEntry) {
+	options := []string{}
+	byLabel := map[string]scriptEntry{}
+	for _, e := range entries {
+		options = append(options, e.Meta.DisplayName)
+		byLabel[e.Meta.DisplayName] = e
+	}
+	choice := rm.rofiMenu("Scripts", options, rm.getEnabledTheme())
+	if entry, ok := byLabel[choice]; ok {
+		rm.dispatchScript(entry)
+	}
+}
+
+// dispatchScript runs a script per its metadata: "custom-menu" hands it to
+// rofi as a script-mode provider (rofi -show <name> -modi "<name>:<script>"),
+// while "run" (the default) execs it directly with its configured args/env.
+func (rm *RofiManager) dispatchScript(entry scriptEntry) {
+	scriptPath := filepath.Join(rm.scriptsDir, entry.Name)
+	if entry.Meta.Mode == "custom-menu" {
+		rofiCmd := []string{"-show", entry.Name, "-modi", fmt.Sprintf("%s:%s", entry.Name, scriptPath)}
+		if theme := rm.getEnabledTheme(); theme != "" {
+			if themePath, err := rm.composeTheme(theme); err == nil && themePath != "" {
+				rofiCmd = append(rofiCmd, "-theme", themePath)
+			}
+		}
+		cmd := exec.Command("rofi", rofiCmd...)
+		cmd.Run()
+		os.Exit(0)
+	}
+	cmd := exec.Command(scriptPath, entry.Meta.Args...)
+	cmd.Env = append(os.Environ(), entry.Meta.Env...)
+	cmd.Start()
+	os.Exit(0)
+}
+
+// editScriptMetadataMenu is the "Edit Script Metadata" menu action: it
+// suspends the rofi menu loop and hands control to $EDITOR on the script's
+// sidecar ini, creating a skeleton one first if none exists yet.
+func (rm *RofiManager) editScriptMetadataMenu(scripts []string) {
+	options := []string{}
+	labelToScript := map[string]string{}
+	for _, script := range scripts {
+		meta := loadScriptMeta(rm.scriptsDir, script)
+		options = append(options, meta.DisplayName)
+		labelToScript[meta.DisplayName] = script
+	}
+	choice := rm.rofiMenu("Edit metadata for", options, rm.getEnabledTheme())
+	script, ok := labelToScript[choice]
+	if !ok {
+		return
+	}
+
+	metaPath := scriptMetaPath(rm.scriptsDir, script)
+	if _, err := os.Stat(metaPath); os.IsNotExist(err) {
+		cfg := ini.Empty()
+		section := cfg.Section("script")
+		section.Key("display_name").SetValue(script)
+		section.Key("mode").SetValue("run")
+		cfg.SaveTo(metaPath)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command(editor, metaPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Run()
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultThemeIndexURL = "https://raw.githubusercontent.com/feketefh/RofiManager/main/themes.json"
+
+// ThemeIndexEntry describes a single theme available in the remote gallery index.
+type ThemeIndexEntry struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"download_url"`
+	SHA256      string `json:"sha256"`
+	Author      string `json:"author"`
+	MTime       string `json:"mtime"`
+}
+
+// InstalledThemeRecord tracks where an installed theme came from so future
+// index updates can detect upstream changes and offer an upgrade.
+type InstalledThemeRecord struct {
+	Name   string `json:"name"`
+	Origin string `json:"origin"`
+	SHA256 string `json:"sha256"`
+}
+
+// ThemeCollection manages the remote theme gallery: fetching the index,
+// previewing and installing themes, and tracking installed origins.
+type ThemeCollection struct {
+	rm                 *RofiManager
+	indexURL           string
+	cacheDir           string
+	indexPath          string
+	installedIndexPath string
+}
+
+func NewThemeCollection(rm *RofiManager) *ThemeCollection {
+	indexURL := os.Getenv("ROFI_MANAGER_THEME_INDEX_URL")
+	if indexURL == "" {
+		indexURL = defaultThemeIndexURL
+	}
+	cacheDir := filepath.Join(rm.baseDir, "cache", "themes")
+	os.MkdirAll(cacheDir, 0755)
+	return &ThemeCollection{
+		rm:                 rm,
+		indexURL:           indexURL,
+		cacheDir:           cacheDir,
+		indexPath:          filepath.Join(cacheDir, "index.json"),
+		installedIndexPath: filepath.Join(rm.baseDir, "themes.index.json"),
+	}
+}
+
+func (tc *ThemeCollection) fetchIndex() ([]ThemeIndexEntry, error) {
+	resp, err := http.Get(tc.indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching theme index: %s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ThemeIndexEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(tc.indexPath, body, 0644); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (tc *ThemeCollection) loadCachedIndex() ([]ThemeIndexEntry, error) {
+	body, err := ioutil.ReadFile(tc.indexPath)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ThemeIndexEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (tc *ThemeCollection) loadInstalledIndex() map[string]InstalledThemeRecord {
+	records := map[string]InstalledThemeRecord{}
+	body, err := ioutil.ReadFile(tc.installedIndexPath)
+	if err != nil {
+		return records
+	}
+	var list []InstalledThemeRecord
+	if err := json.Unmarshal(body, &list); err != nil {
+		return records
+	}
+	for _, r := range list {
+		records[r.Name] = r
+	}
+	return records
+}
+
+func (tc *ThemeCollection) saveInstalledIndex(records map[string]InstalledThemeRecord) error {
+	list := make([]InstalledThemeRecord, 0, len(records))
+	for _, r := range records {
+		list = append(list, r)
+	}
+	body, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(tc.installedIndexPath, body, 0644)
+}
+
+// updateThemeIndex is the "Update Theme Index" menu action: it refreshes the
+// cached index and reports how many themes have upstream changes pending.
+func (tc *ThemeCollection) updateThemeIndex() {
+	entries, err := tc.fetchIndex()
+	if err != nil {
+		tc.rm.showInfo(fmt.Sprintf("Failed to update theme index:\n%s", err))
+		return
+	}
+	installed := tc.loadInstalledIndex()
+	upgradable := 0
+	for _, e := range entries {
+		if rec, ok := installed[e.Name]; ok && rec.SHA256 != e.SHA256 {
+			upgradable++
+		}
+	}
+	tc.rm.showInfo(fmt.Sprintf("Theme index updated: %d themes available, %d upgrade(s) pending.", len(entries), upgradable))
+}
+
+// browseOnlineThemes is the "Browse Online Themes" menu action.
+func (tc *ThemeCollection) browseOnlineThemes() {
+	entries, err := tc.loadCachedIndex()
+	if err != nil {
+		entries, err = tc.fetchIndex()
+		if err != nil {
+			tc.rm.showInfo(fmt.Sprintf("Failed to load theme index:\n%s", err))
+			return
+		}
+	}
+	if len(entries) == 0 {
+		tc.rm.showInfo("No themes found in the index.")
+		return
+	}
+	installed := tc.loadInstalledIndex()
+	options := []string{}
+	for _, e := range entries {
+		mark := "[ ]"
+		if rec, ok := installed[e.Name]; ok {
+			mark = "[x]"
+			if rec.SHA256 != e.SHA256 {
+				mark = "[u]"
+			}
+		}
+		options = append(options, fmt.Sprintf("%s %s (by %s)", mark, e.Name, e.Author))
+	}
+	choice := tc.rm.rofiMenu("Browse Online Themes", options, tc.rm.getEnabledTheme())
+	if choice == "" {
+		return
+	}
+	for i, opt := range options {
+		if opt == choice {
+			tc.promptInstall(entries[i])
+			return
+		}
+	}
+}
+
+// previewThemeMenu is the "Preview Theme" menu action: pick any indexed
+// theme and preview it directly, without going through the install prompt.
+func (tc *ThemeCollection) previewThemeMenu() {
+	entries, err := tc.loadCachedIndex()
+	if err != nil {
+		entries, err = tc.fetchIndex()
+		if err != nil {
+			tc.rm.showInfo(fmt.Sprintf("Failed to load theme index:\n%s", err))
+			return
+		}
+	}
+	if len(entries) == 0 {
+		tc.rm.showInfo("No themes found in the index.")
+		return
+	}
+	options := []string{}
+	for _, e := range entries {
+		options = append(options, fmt.Sprintf("%s (by %s)", e.Name, e.Author))
+	}
+	choice := tc.rm.rofiMenu("Preview Theme", options, tc.rm.getEnabledTheme())
+	if choice == "" {
+		return
+	}
+	for i, opt := range options {
+		if opt == choice {
+			tc.previewTheme(entries[i])
+			return
+		}
+	}
+}
+
+func (tc *ThemeCollection) promptInstall(entry ThemeIndexEntry) {
+	action := tc.rm.rofiMenu(fmt.Sprintf("%s by %s", entry.Name, entry.Author), []string{"Preview", "Install", "Cancel"}, tc.rm.getEnabledTheme())
+	switch action {
+	case "Preview":
+		tc.previewTheme(entry)
+	case "Install":
+		tc.installTheme(entry)
+	}
+}
+
+// previewTheme downloads the theme to a temp file and launches rofi with it
+// against a handful of dummy entries so the user can see it before installing.
+func (tc *ThemeCollection) previewTheme(entry ThemeIndexEntry) {
+	tempTheme, cleanup, err := tc.downloadToTemp(entry.DownloadURL, filepath.Base(entry.DownloadURL))
+	if err != nil {
+		tc.rm.showInfo(fmt.Sprintf("Failed to download theme for preview:\n%s", err))
+		return
+	}
+	defer cleanup()
+	dummyEntries := []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"}
+	tc.rm.rofiMenuWithThemePath(fmt.Sprintf("Preview: %s", entry.Name), dummyEntries, tempTheme)
+}
+
+func (tc *ThemeCollection) downloadToTemp(url, suggestedName string) (string, func(), error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status downloading theme: %s", resp.Status)
+	}
+	tmpFile, err := ioutil.TempFile("", "rofi-manager-theme-*-"+suggestedName)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tmpFile.Close()
+	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmpFile.Name()) }
+	return tmpFile.Name(), cleanup, nil
+}
+
+func verifySHA256(path, expected string) (bool, error) {
+	if expected == "" {
+		return true, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false, err
+	}
+	actual := hex.EncodeToString(h.Sum(nil))
+	return strings.EqualFold(actual, expected), nil
+}
+
+// extractZipThemes extracts every member ending in .rasi from the given zip
+// archive into destDir, returning the list of installed file names.
+func extractZipThemes(zipPath, destDir string) ([]string, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	installed := []string{}
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".rasi") {
+			continue
+		}
+		name := filepath.Base(f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			return installed, err
+		}
+		dest := filepath.Join(destDir, name)
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			rc.Close()
+			return installed, err
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		out.Close()
+		if err != nil {
+			return installed, err
+		}
+		installed = append(installed, name)
+	}
+	return installed, nil
+}
+
+// installTheme downloads entry's theme, verifies its checksum, installs it
+// (expanding zip archives into their member .rasi files) and offers to
+// enable it immediately.
+func (tc *ThemeCollection) installTheme(entry ThemeIndexEntry) {
+	tmpPath, cleanup, err := tc.downloadToTemp(entry.DownloadURL, filepath.Base(entry.DownloadURL))
+	if err != nil {
+		tc.rm.showInfo(fmt.Sprintf("Failed to download theme:\n%s", err))
+		return
+	}
+	defer cleanup()
+
+	ok, err := verifySHA256(tmpPath, entry.SHA256)
+	if err != nil {
+		tc.rm.showInfo(fmt.Sprintf("Failed to verify theme checksum:\n%s", err))
+		return
+	}
+	if !ok {
+		tc.rm.showInfo(fmt.Sprintf("Checksum mismatch for %s, refusing to install.", entry.Name))
+		return
+	}
+
+	var installedFiles []string
+	if strings.HasSuffix(strings.ToLower(entry.DownloadURL), ".zip") {
+		installedFiles, err = extractZipThemes(tmpPath, tc.rm.themesDir)
+		if err != nil {
+			tc.rm.showInfo(fmt.Sprintf("Failed to extract theme archive:\n%s", err))
+			return
+		}
+		if len(installedFiles) == 0 {
+			tc.rm.showInfo("Archive did not contain any .rasi themes.")
+			return
+		}
+	} else {
+		name := entry.Name
+		if !strings.HasSuffix(name, ".rasi") {
+			name += ".rasi"
+		}
+		dest := filepath.Join(tc.rm.themesDir, name)
+		if err := copyFile(tmpPath, dest, 0644); err != nil {
+			tc.rm.showInfo(fmt.Sprintf("Failed to install theme:\n%s", err))
+			return
+		}
+		installedFiles = []string{name}
+	}
+
+	installed := tc.loadInstalledIndex()
+	installed[entry.Name] = InstalledThemeRecord{Name: entry.Name, Origin: entry.DownloadURL, SHA256: entry.SHA256}
+	tc.saveInstalledIndex(installed)
+
+	enable := tc.rm.rofiMenu(fmt.Sprintf("Installed %s. Enable now?", entry.Name), []string{"No", "Yes"}, tc.rm.getEnabledTheme())
+	if enable == "Yes" {
+		tc.rm.setEnabledTheme(installedFiles[0])
+	}
+}
+
+func copyFile(src, dest string, perm os.FileMode) error {
+	input, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dest, input, perm)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// composedThemeDir returns the cache directory holding resolved theme
+// compositions, distinct from the remote gallery's own cache dir.
+func (rm *RofiManager) composedThemeDir() string {
+	return filepath.Join(rm.baseDir, "cache", "composed")
+}
+
+// themeMetaPath returns the sidecar meta file path for a theme, e.g.
+// "nord.rasi" -> "nord.rasi.meta.ini".
+func (rm *RofiManager) themeMetaPath(theme string) string {
+	return filepath.Join(rm.themesDir, theme+".meta.ini")
+}
+
+// parseThemeExtends reads a theme's parent list, either from its sidecar
+// <theme>.meta.ini ([theme] extends = a.rasi, b.rasi) or from a
+// "// rofi-manager: extends = a.rasi, b.rasi" header comment in the .rasi
+// file itself. Returns nil if the theme declares no parents.
+func (rm *RofiManager) parseThemeExtends(theme string) ([]string, error) {
+	metaPath := rm.themeMetaPath(theme)
+	if meta, err := ini.Load(metaPath); err == nil {
+		val := meta.Section("theme").Key("extends").String()
+		return splitCSV(val), nil
+	}
+
+	themePath := filepath.Join(rm.themesDir, theme)
+	body, err := ioutil.ReadFile(themePath)
+	if err != nil {
+		return nil, fmt.Errorf("missing theme: %s", theme)
+	}
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "// rofi-manager:") {
+			continue
+		}
+		header := strings.TrimSpace(strings.TrimPrefix(line, "// rofi-manager:"))
+		for _, field := range strings.Split(header, ";") {
+			field = strings.TrimSpace(field)
+			if key, val, ok := strings.Cut(field, "="); ok && strings.TrimSpace(key) == "extends" {
+				return splitCSV(val), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// resolveThemeChain walks a theme's extends chain depth-first, returning the
+// ordered list of theme files to concatenate: root ancestor first, the
+// requested theme last. It reports missing parents and inheritance cycles.
+func (rm *RofiManager) resolveThemeChain(theme string) ([]string, error) {
+	visiting := map[string]bool{}
+	var chain []string
+	seen := map[string]bool{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visiting[name] {
+			return fmt.Errorf("cycle detected in theme inheritance at %q", name)
+		}
+		if _, err := os.Stat(filepath.Join(rm.themesDir, name)); err != nil {
+			return fmt.Errorf("missing parent theme: %s", name)
+		}
+		visiting[name] = true
+		parents, err := rm.parseThemeExtends(name)
+		if err != nil {
+			return err
+		}
+		for _, parent := range parents {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		if !seen[name] {
+			chain = append(chain, name)
+			seen[name] = true
+		}
+		return nil
+	}
+
+	if err := visit(theme); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// chainMTime returns the newest modification time across a theme chain and
+// its sidecar meta files, used to decide whether a cached composition is stale.
+func (rm *RofiManager) chainMTime(chain []string) int64 {
+	var newest int64
+	for _, theme := range chain {
+		for _, path := range []string{filepath.Join(rm.themesDir, theme), rm.themeMetaPath(theme)} {
+			if info, err := os.Stat(path); err == nil {
+				if t := info.ModTime().Unix(); t > newest {
+					newest = t
+				}
+			}
+		}
+	}
+	return newest
+}
+
+// composeTheme resolves theme's inheritance chain and returns the path to a
+// single .rasi file combining all ancestors in order. Themes with no
+// extends resolve directly to their file under themesDir with no
+// composition step. The composed result is cached and only rebuilt when an
+// ancestor's mtime moves past the cached file's.
+func (rm *RofiManager) composeTheme(theme string) (string, error) {
+	if theme == "" {
+		return "", nil
+	}
+	chain, err := rm.resolveThemeChain(theme)
+	if err != nil {
+		return "", err
+	}
+	if len(chain) == 1 {
+		return filepath.Join(rm.themesDir, theme), nil
+	}
+
+	os.MkdirAll(rm.composedThemeDir(), 0755)
+	composedPath := filepath.Join(rm.composedThemeDir(), theme)
+	if info, err := os.Stat(composedPath); err == nil {
+		if info.ModTime().Unix() >= rm.chainMTime(chain) {
+			return composedPath, nil
+		}
+	}
+
+	var out strings.Builder
+	for _, ancestor := range chain {
+		body, err := ioutil.ReadFile(filepath.Join(rm.themesDir, ancestor))
+		if err != nil {
+			return "", fmt.Errorf("missing parent theme: %s", ancestor)
+		}
+		out.Write(body)
+		out.WriteString("\n")
+	}
+	if err := ioutil.WriteFile(composedPath, []byte(out.String()), 0644); err != nil {
+		return "", err
+	}
+	return composedPath, nil
+}